@@ -0,0 +1,37 @@
+// Package beacon lets the node mix externally verifiable randomness into
+// block-proposer selection instead of relying solely on local entropy.
+// HTTPBeacon, a drand-style client, is the production implementation;
+// Local is an in-memory stand-in for tests.
+package beacon
+
+import (
+	"context"
+
+	"github.com/bytom/errors"
+)
+
+// Entry is a single verified round of beacon output.
+type Entry struct {
+	Round      uint64
+	Randomness []byte
+	Signature  []byte
+}
+
+// RandomnessBeacon supplies externally verifiable randomness to block
+// proposers. Entry and LatestRound answer from whatever's been fetched
+// and verified so far; Run drives the fetching until ctx is canceled.
+type RandomnessBeacon interface {
+	// Entry returns the verified randomness for round, fetching and
+	// verifying it first if it isn't cached yet.
+	Entry(round uint64) ([]byte, error)
+
+	// LatestRound returns the highest round currently cached. It is 0
+	// before the first successful fetch.
+	LatestRound() uint64
+
+	// Run fetches new rounds as they become available until ctx is
+	// canceled.
+	Run(ctx context.Context) error
+}
+
+var errRoundNotFound = errors.New("beacon round not found")
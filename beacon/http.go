@@ -0,0 +1,177 @@
+package beacon
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bytom/errors"
+	"github.com/drand/kyber"
+	"github.com/drand/kyber/pairing/bn256"
+)
+
+const (
+	defaultPeriod  = 30 * time.Second
+	requestTimeout = 5 * time.Second
+)
+
+// HTTPBeacon is a RandomnessBeacon that polls a drand-style HTTP relay
+// for new rounds on a fixed chain, verifying each one's BLS signature
+// against the chain's public key before caching it.
+type HTTPBeacon struct {
+	urls      []string
+	chainHash string
+	period    time.Duration
+	client    *http.Client
+	publicKey kyber.Point
+
+	mu      sync.RWMutex
+	entries map[uint64]Entry
+	latest  uint64
+}
+
+type httpRound struct {
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness"`
+	Signature         string `json:"signature"`
+	PreviousSignature string `json:"previous_signature"`
+}
+
+// NewHTTP builds an HTTPBeacon that polls urls, tried in order until one
+// answers, for chainHash's randomness chain, verifying rounds against
+// publicKey (the chain's BLS public key, as published by the relay). A
+// zero period defaults to defaultPeriod.
+func NewHTTP(urls []string, chainHash string, publicKey []byte, period time.Duration) (*HTTPBeacon, error) {
+	pub := bn256.NewSuiteG2().G2().Point()
+	if err := pub.UnmarshalBinary(publicKey); err != nil {
+		return nil, errors.Wrap(err, "parsing beacon chain public key")
+	}
+	if period == 0 {
+		period = defaultPeriod
+	}
+	return &HTTPBeacon{
+		urls:      urls,
+		chainHash: chainHash,
+		period:    period,
+		client:    &http.Client{Timeout: requestTimeout},
+		publicKey: pub,
+		entries:   make(map[uint64]Entry),
+	}, nil
+}
+
+// Run polls for the latest round every period until ctx is canceled. A
+// failed poll isn't fatal; the next tick tries again.
+func (b *HTTPBeacon) Run(ctx context.Context) error {
+	ticker := time.NewTicker(b.period)
+	defer ticker.Stop()
+	for {
+		b.fetchLatest(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *HTTPBeacon) fetchLatest(ctx context.Context) {
+	for _, url := range b.urls {
+		hr, err := b.fetch(ctx, url, "latest")
+		if err != nil {
+			continue
+		}
+		if err := b.verifyAndStore(hr); err == nil {
+			return
+		}
+	}
+}
+
+func (b *HTTPBeacon) fetch(ctx context.Context, baseURL, round string) (httpRound, error) {
+	req, err := http.NewRequest("GET", baseURL+"/"+b.chainHash+"/public/"+round, nil)
+	if err != nil {
+		return httpRound{}, err
+	}
+	resp, err := b.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return httpRound{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return httpRound{}, errors.New("beacon relay returned " + resp.Status)
+	}
+
+	var hr httpRound
+	if err := json.NewDecoder(resp.Body).Decode(&hr); err != nil {
+		return httpRound{}, errors.Wrap(err, "decoding beacon round")
+	}
+	return hr, nil
+}
+
+// verifyAndStore checks hr's BLS signature against b.publicKey and, if it
+// verifies, caches the round. Rounds that fail verification are dropped
+// rather than cached, so a compromised or misbehaving relay can't feed
+// proposers unverified randomness.
+func (b *HTTPBeacon) verifyAndStore(hr httpRound) error {
+	sig, err := hex.DecodeString(hr.Signature)
+	if err != nil {
+		return errors.Wrap(err, "decoding beacon signature")
+	}
+	randomness, err := hex.DecodeString(hr.Randomness)
+	if err != nil {
+		return errors.Wrap(err, "decoding beacon randomness")
+	}
+	previousSig, err := hex.DecodeString(hr.PreviousSignature)
+	if err != nil {
+		return errors.Wrap(err, "decoding beacon previous signature")
+	}
+
+	if err := verify(b.publicKey, hr.Round, previousSig, sig); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[hr.Round] = Entry{Round: hr.Round, Randomness: randomness, Signature: sig}
+	if hr.Round > b.latest {
+		b.latest = hr.Round
+	}
+	return nil
+}
+
+func (b *HTTPBeacon) Entry(round uint64) ([]byte, error) {
+	b.mu.RLock()
+	e, ok := b.entries[round]
+	b.mu.RUnlock()
+	if ok {
+		return e.Randomness, nil
+	}
+
+	var lastErr error
+	for _, url := range b.urls {
+		hr, err := b.fetch(context.Background(), url, strconv.FormatUint(round, 10))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := b.verifyAndStore(hr); err != nil {
+			return nil, err
+		}
+		b.mu.RLock()
+		e := b.entries[round]
+		b.mu.RUnlock()
+		return e.Randomness, nil
+	}
+	return nil, errors.Wrap(lastErr, "fetching beacon round")
+}
+
+func (b *HTTPBeacon) LatestRound() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.latest
+}
+
+var _ RandomnessBeacon = (*HTTPBeacon)(nil)
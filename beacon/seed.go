@@ -0,0 +1,16 @@
+package beacon
+
+import "crypto/sha256"
+
+// MixSeed folds a beacon round's verified randomness into base (e.g. the
+// chain's existing proposer-selection or tx-ordering seed), producing the
+// value protocol.Chain should use in its place. It's a pure, deterministic
+// function so every node mixing the same (entry, base) pair agrees on the
+// result; node.Node.reportProposerSeed calls it on every committed block
+// and hands the result to chain.SetProposerSeed.
+func MixSeed(entry Entry, base []byte) []byte {
+	h := sha256.New()
+	h.Write(base)
+	h.Write(entry.Randomness)
+	return h.Sum(nil)
+}
@@ -0,0 +1,22 @@
+package beacon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMixSeedDeterministic(t *testing.T) {
+	entry := Entry{Round: 1, Randomness: []byte("randomness")}
+	base := []byte("base-seed")
+
+	a := MixSeed(entry, base)
+	b := MixSeed(entry, base)
+	if !bytes.Equal(a, b) {
+		t.Fatal("MixSeed is not deterministic")
+	}
+
+	other := MixSeed(Entry{Round: 2, Randomness: []byte("different")}, base)
+	if bytes.Equal(a, other) {
+		t.Fatal("MixSeed did not change with a different entry")
+	}
+}
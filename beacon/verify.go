@@ -0,0 +1,39 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/bytom/errors"
+	"github.com/drand/kyber"
+	"github.com/drand/kyber/pairing/bn256"
+	"github.com/drand/kyber/sign/bls"
+)
+
+// scheme is the BLS signature scheme rounds are verified with: the bn256
+// pairing curve, signatures on G1 and public keys on G2 (the group
+// NewHTTP parses the chain public key into). It has no per-call state,
+// so one instance is shared by every HTTPBeacon.
+var scheme = bls.NewSchemeOnG1(bn256.NewSuiteG2())
+
+// roundMessage is the signed payload for round, following drand's chained
+// beacon convention: sha256(round || previous round's signature).
+func roundMessage(round uint64, previousSignature []byte) []byte {
+	h := sha256.New()
+	var roundBytes [8]byte
+	binary.BigEndian.PutUint64(roundBytes[:], round)
+	h.Write(roundBytes[:])
+	h.Write(previousSignature)
+	return h.Sum(nil)
+}
+
+// verify checks that sig is a valid signature over round's message under
+// the chain public key pub, refusing to let an unverifiable round reach
+// the cache.
+func verify(pub kyber.Point, round uint64, previousSignature, sig []byte) error {
+	msg := roundMessage(round, previousSignature)
+	if err := scheme.Verify(pub, msg, sig); err != nil {
+		return errors.Wrap(err, "verifying beacon round signature")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package beacon
+
+import (
+	"testing"
+
+	"github.com/drand/kyber/pairing/bn256"
+	"github.com/drand/kyber/sign/bls"
+	"github.com/drand/kyber/util/random"
+)
+
+func TestRoundMessageDeterministic(t *testing.T) {
+	a := roundMessage(7, []byte("previous-sig"))
+	b := roundMessage(7, []byte("previous-sig"))
+	if string(a) != string(b) {
+		t.Fatal("roundMessage is not deterministic")
+	}
+
+	if c := roundMessage(8, []byte("previous-sig")); string(a) == string(c) {
+		t.Fatal("roundMessage did not change with round")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	priv, pub := bls.NewKeyPair(bn256.NewSuiteG2(), random.New())
+	round := uint64(42)
+	previousSignature := []byte("previous-signature")
+	msg := roundMessage(round, previousSignature)
+
+	sig, err := scheme.Sign(priv, msg)
+	if err != nil {
+		t.Fatalf("signing test round: %v", err)
+	}
+
+	if err := verify(pub, round, previousSignature, sig); err != nil {
+		t.Fatalf("verify rejected a validly signed round: %v", err)
+	}
+
+	if err := verify(pub, round+1, previousSignature, sig); err == nil {
+		t.Fatal("verify accepted a signature for the wrong round")
+	}
+
+	tampered := append([]byte{}, sig...)
+	tampered[0] ^= 0xff
+	if err := verify(pub, round, previousSignature, tampered); err == nil {
+		t.Fatal("verify accepted a tampered signature")
+	}
+}
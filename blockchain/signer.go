@@ -0,0 +1,26 @@
+package blockchain
+
+import "context"
+
+// XPub is the public half of a signing key: its ID (how callers refer to
+// it), its alias, and its extended public key bytes. Both Signer
+// implementations (pseudohsm.HSM, remotehsm.Client) return and accept
+// these.
+type XPub struct {
+	KeyID string
+	Alias string
+	XPub  []byte
+}
+
+// Signer is the interface the blockchain reactor signs transactions
+// through, so it never needs to know whether it was handed
+// pseudohsm.HSM (keys held in-process) or remotehsm.Client (keys held by
+// a remote signer reached over gRPC). NewBlockchainReactor takes Signer
+// directly; bcReactorServiceConstructor (services_builtin.go) passes
+// whichever implementation hsmServiceConstructor built straight through.
+type Signer interface {
+	ListKeys(ctx context.Context) ([]XPub, error)
+	XPub(ctx context.Context, keyID string) (*XPub, error)
+	Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+	CreateKey(ctx context.Context, alias, password string) (*XPub, error)
+}
@@ -0,0 +1,220 @@
+package txindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bytom/blockchain/txindex/query"
+	"github.com/bytom/errors"
+	"github.com/bytom/protocol/bc"
+	"github.com/bytom/protocol/bc/legacy"
+	"github.com/bytom/types"
+	cmn "github.com/tendermint/tmlibs/common"
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+const (
+	listenerID   = "txindex"
+	txPrefix     = "tx/"
+	heightPrefix = "txh/"
+	tagPrefix    = "tag/"
+)
+
+// KV is a dbm.DB-backed Indexer. It subscribes to block-committed events
+// on the node's event switch and, as each block lands, writes one row per
+// transaction keyed by hash (for Tx), one row per transaction keyed by
+// height+hash (for Search's most-recent-first ordering), plus one row per
+// indexed tag.
+type KV struct {
+	cmn.BaseService
+
+	db   dbm.DB
+	evsw types.EventSwitch
+}
+
+// NewKV builds a KV indexer on top of db. Callers must give it a logger
+// via SetLogger before starting it; call OnStart (via cmn.Service) once
+// evsw is running to begin indexing committed blocks.
+func NewKV(db dbm.DB, evsw types.EventSwitch) *KV {
+	kv := &KV{db: db, evsw: evsw}
+	kv.BaseService = *cmn.NewBaseService(nil, "TxIndexer", kv)
+	return kv
+}
+
+func (kv *KV) OnStart() error {
+	types.AddListenerForEvent(kv.evsw, listenerID, types.EventStringNewBlock(), func(ed types.EventData) {
+		block, ok := ed.(types.EventDataNewBlock)
+		if !ok {
+			return
+		}
+		if err := kv.IndexBlock(context.Background(), block.Block); err != nil {
+			kv.Logger.Error("txindex: failed to index block", "height", block.Block.Height, "error", err)
+		}
+	})
+	return nil
+}
+
+func (kv *KV) OnStop() {
+	kv.evsw.RemoveListener(listenerID)
+}
+
+func (kv *KV) IndexBlock(ctx context.Context, b *legacy.Block) error {
+	batch := kv.db.NewBatch()
+	for _, tx := range b.Transactions {
+		result := &TxResult{Tx: tx, Height: b.Height, Tags: extractTags(tx)}
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return errors.Wrap(err, "marshaling tx result")
+		}
+
+		hash := tx.Hash()
+		batch.Set(txKey(hash), raw)
+		batch.Set(heightKey(b.Height, hash), raw)
+		for k, v := range result.Tags {
+			batch.Set(tagKey(k, v, hash), nil)
+		}
+	}
+	batch.Write()
+	return nil
+}
+
+func (kv *KV) Tx(ctx context.Context, hash bc.Hash) (*TxResult, error) {
+	raw := kv.db.Get(txKey(hash))
+	if raw == nil {
+		return nil, nil
+	}
+	result := new(TxResult)
+	if err := json.Unmarshal(raw, result); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling tx result")
+	}
+	return result, nil
+}
+
+// Search scans every indexed transaction, most recent first, and keeps
+// the ones matching q. It's a straightforward linear scan over the
+// height-ordered index rather than using the tag index, which is enough
+// for the modest indexes this node expects to hold; a tag-driven scan can
+// be layered in later without changing the Indexer interface.
+func (kv *KV) Search(ctx context.Context, q string, after string, limit int) ([]*TxResult, string, error) {
+	parsed, err := query.Parse(q)
+	if err != nil {
+		return nil, "", err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var (
+		results []*TxResult
+		next    string
+		skip    = after != ""
+		last    string
+	)
+	iter := kv.db.ReverseIterator(nil, nil)
+	defer iter.Close()
+	for iter.Next() {
+		if !isHeightKey(iter.Key()) {
+			continue
+		}
+		result := new(TxResult)
+		if err := json.Unmarshal(iter.Value(), result); err != nil {
+			return nil, "", errors.Wrap(err, "unmarshaling tx result")
+		}
+
+		fields := make(map[string]interface{}, len(result.Tags)+1)
+		for k, v := range result.Tags {
+			fields[k] = v
+		}
+		fields["height"] = int64(result.Height)
+		if !parsed.Match(fields) {
+			continue
+		}
+
+		hash := result.Tx.Hash().String()
+		if skip {
+			if hash == after {
+				skip = false
+			}
+			continue
+		}
+		if len(results) == limit {
+			next = last
+			break
+		}
+		results = append(results, result)
+		last = hash
+	}
+	return results, next, nil
+}
+
+// extractTags pulls the TagKeys fields out of tx: the asset ID of every
+// input/output, and, from any reference data JSON-encoded as
+// {"account_id": "..."} (the convention blockchain/account uses when it
+// annotates a tx it owns), the owning account ID. The raw reference data
+// itself is indexed verbatim under "reference_data" so callers can search
+// on it directly. Tags only holds one value per key, so a tx touching
+// more than one asset or account is tagged with the last one seen.
+func extractTags(tx *legacy.Tx) Tags {
+	tags := make(Tags)
+
+	index := func(assetID bc.AssetID, referenceData []byte) {
+		tags["asset_id"] = assetID.String()
+		if len(referenceData) == 0 {
+			return
+		}
+		tags["reference_data"] = string(referenceData)
+		if accountID := accountIDFromReferenceData(referenceData); accountID != "" {
+			tags["account_id"] = accountID
+		}
+	}
+
+	for _, in := range tx.Inputs {
+		aa := in.AssetAmount()
+		index(aa.AssetId, in.ReferenceData)
+	}
+	for _, out := range tx.Outputs {
+		index(out.AssetAmount.AssetId, out.ReferenceData)
+	}
+
+	indexed := make(Tags, len(tags))
+	for _, key := range TagKeys {
+		if v, ok := tags[key]; ok {
+			indexed[key] = v
+		}
+	}
+	return indexed
+}
+
+// accountIDFromReferenceData pulls "account_id" out of a JSON-encoded
+// reference data blob. Data that isn't JSON, or has no such field, just
+// means this side of the tx isn't tagged with an account; that's not an
+// error.
+func accountIDFromReferenceData(data []byte) string {
+	var ref struct {
+		AccountID string `json:"account_id"`
+	}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return ""
+	}
+	return ref.AccountID
+}
+
+func txKey(hash bc.Hash) []byte {
+	return append([]byte(txPrefix), []byte(hash.String())...)
+}
+
+// heightKey orders lexicographically by height so a ReverseIterator walks
+// transactions most-recent-first; it's a secondary index over the same
+// TxResult stored under txKey.
+func heightKey(height uint64, hash bc.Hash) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", heightPrefix, height, hash.String()))
+}
+
+func isHeightKey(key []byte) bool {
+	return len(key) > len(heightPrefix) && string(key[:len(heightPrefix)]) == heightPrefix
+}
+
+func tagKey(key, value string, hash bc.Hash) []byte {
+	return []byte(tagPrefix + key + "/" + value + "/" + hash.String())
+}
@@ -0,0 +1,211 @@
+package txindex
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bytom/protocol/bc"
+	"github.com/bytom/protocol/bc/legacy"
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+// heightKey/isHeightKey/accountIDFromReferenceData are tested directly
+// here since they need nothing but a zero-value bc.Hash. IndexBlock and
+// Search are driven end-to-end below against fakeDB, a minimal in-memory
+// stand-in for dbm.DB.
+
+func TestHeightKeyOrdering(t *testing.T) {
+	var hash bc.Hash
+	low := heightKey(3, hash)
+	high := heightKey(10, hash)
+	if !(string(low) < string(high)) {
+		t.Fatalf("heightKey(3) should sort before heightKey(10), got %q >= %q", low, high)
+	}
+}
+
+func TestIsHeightKey(t *testing.T) {
+	var hash bc.Hash
+	if !isHeightKey(heightKey(5, hash)) {
+		t.Fatal("isHeightKey rejected a real height key")
+	}
+	if isHeightKey(txKey(hash)) {
+		t.Fatal("isHeightKey accepted a tx key")
+	}
+	if isHeightKey(tagKey("asset_id", "X", hash)) {
+		t.Fatal("isHeightKey accepted a tag key")
+	}
+}
+
+func TestAccountIDFromReferenceData(t *testing.T) {
+	cases := []struct {
+		data string
+		want string
+	}{
+		{`{"account_id":"acc1"}`, "acc1"},
+		{`{"other_field":"x"}`, ""},
+		{`not json`, ""},
+		{``, ""},
+	}
+	for _, c := range cases {
+		got := accountIDFromReferenceData([]byte(c.data))
+		if got != c.want {
+			t.Errorf("accountIDFromReferenceData(%q) = %q, want %q", c.data, got, c.want)
+		}
+	}
+}
+
+func TestTagKeyContainsKeyAndValue(t *testing.T) {
+	var hash bc.Hash
+	key := string(tagKey("asset_id", "abc123", hash))
+	if !strings.Contains(key, "asset_id") || !strings.Contains(key, "abc123") {
+		t.Fatalf("tagKey %q missing key or value", key)
+	}
+}
+
+func TestIndexBlockAndSearch(t *testing.T) {
+	db := newFakeDB()
+	kv := NewKV(db, nil)
+	ctx := context.Background()
+
+	tx1 := legacy.NewTx(legacy.TxData{})
+	tx2 := legacy.NewTx(legacy.TxData{Version: 1})
+
+	if err := kv.IndexBlock(ctx, &legacy.Block{
+		BlockHeader:  legacy.BlockHeader{Height: 1},
+		Transactions: []*legacy.Tx{tx1},
+	}); err != nil {
+		t.Fatalf("IndexBlock(height 1): %v", err)
+	}
+	if err := kv.IndexBlock(ctx, &legacy.Block{
+		BlockHeader:  legacy.BlockHeader{Height: 2},
+		Transactions: []*legacy.Tx{tx2},
+	}); err != nil {
+		t.Fatalf("IndexBlock(height 2): %v", err)
+	}
+
+	got, err := kv.Tx(ctx, tx2.Hash())
+	if err != nil {
+		t.Fatalf("Tx: %v", err)
+	}
+	if got == nil || got.Height != 2 {
+		t.Fatalf("Tx(tx2) = %+v, want height 2", got)
+	}
+
+	page1, next, err := kv.Search(ctx, "height>0", "", 1)
+	if err != nil {
+		t.Fatalf("Search page 1: %v", err)
+	}
+	if len(page1) != 1 || page1[0].Height != 2 {
+		t.Fatalf("Search page 1 = %+v, want one result at height 2 (most recent first)", page1)
+	}
+	if want := tx2.Hash().String(); next != want {
+		t.Fatalf("Search page 1 cursor = %q, want %q", next, want)
+	}
+
+	page2, _, err := kv.Search(ctx, "height>0", next, 10)
+	if err != nil {
+		t.Fatalf("Search page 2: %v", err)
+	}
+	if len(page2) != 1 || page2[0].Height != 1 {
+		t.Fatalf("Search page 2 = %+v, want one result at height 1", page2)
+	}
+
+	if none, _, err := kv.Search(ctx, "height>100", "", 10); err != nil || len(none) != 0 {
+		t.Fatalf("Search height>100 = %+v, %v, want no matches", none, err)
+	}
+}
+
+// fakeDB is a minimal, sorted, in-memory stand-in for dbm.DB. It exists
+// solely to let KV's IndexBlock/Search be exercised without a real
+// on-disk database.
+type fakeDB struct {
+	data map[string][]byte
+}
+
+var _ dbm.DB = (*fakeDB)(nil)
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{data: make(map[string][]byte)}
+}
+
+func (db *fakeDB) Get(key []byte) []byte { return db.data[string(key)] }
+
+func (db *fakeDB) Has(key []byte) bool {
+	_, ok := db.data[string(key)]
+	return ok
+}
+
+func (db *fakeDB) Set(key, value []byte)     { db.data[string(key)] = value }
+func (db *fakeDB) SetSync(key, value []byte) { db.Set(key, value) }
+func (db *fakeDB) Delete(key []byte)         { delete(db.data, string(key)) }
+func (db *fakeDB) DeleteSync(key []byte)     { db.Delete(key) }
+func (db *fakeDB) Close()                    {}
+func (db *fakeDB) Print()                    {}
+func (db *fakeDB) Stats() map[string]string  { return nil }
+
+func (db *fakeDB) sortedKeys(start, end []byte) []string {
+	keys := make([]string, 0, len(db.data))
+	for k := range db.data {
+		if start != nil && k < string(start) {
+			continue
+		}
+		if end != nil && k >= string(end) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (db *fakeDB) Iterator(start, end []byte) dbm.Iterator {
+	return &fakeIterator{db: db, keys: db.sortedKeys(start, end), pos: -1}
+}
+
+func (db *fakeDB) ReverseIterator(start, end []byte) dbm.Iterator {
+	keys := db.sortedKeys(start, end)
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return &fakeIterator{db: db, keys: keys, pos: -1}
+}
+
+func (db *fakeDB) NewBatch() dbm.Batch { return &fakeBatch{db: db} }
+
+type fakeIterator struct {
+	db   *fakeDB
+	keys []string
+	pos  int
+}
+
+func (it *fakeIterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.keys)
+}
+
+func (it *fakeIterator) Key() []byte   { return []byte(it.keys[it.pos]) }
+func (it *fakeIterator) Value() []byte { return it.db.data[it.keys[it.pos]] }
+func (it *fakeIterator) Close()        {}
+
+type fakeBatch struct {
+	db      *fakeDB
+	pending [][2][]byte
+}
+
+func (b *fakeBatch) Set(key, value []byte) {
+	b.pending = append(b.pending, [2][]byte{key, value})
+}
+
+func (b *fakeBatch) Delete(key []byte) {
+	b.pending = append(b.pending, [2][]byte{key, nil})
+}
+
+func (b *fakeBatch) Write() {
+	for _, kv := range b.pending {
+		b.db.data[string(kv[0])] = kv[1]
+	}
+}
+
+func (b *fakeBatch) WriteSync() { b.Write() }
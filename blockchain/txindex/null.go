@@ -0,0 +1,21 @@
+package txindex
+
+import (
+	"context"
+
+	"github.com/bytom/protocol/bc"
+	"github.com/bytom/protocol/bc/legacy"
+)
+
+// Null is an Indexer that keeps no state and answers every query with
+// "not found". It's used when INDEX_TRANSACTIONS is disabled, so the rest
+// of the node can depend on an Indexer unconditionally.
+type Null struct{}
+
+func (Null) IndexBlock(ctx context.Context, b *legacy.Block) error { return nil }
+
+func (Null) Tx(ctx context.Context, hash bc.Hash) (*TxResult, error) { return nil, nil }
+
+func (Null) Search(ctx context.Context, query string, after string, limit int) ([]*TxResult, string, error) {
+	return nil, "", nil
+}
@@ -0,0 +1,126 @@
+// Package query implements the small predicate language accepted by the
+// tx_search RPC: conditions of the form `key='value'` or `key>123`,
+// joined with AND. It intentionally supports only what tx_search needs,
+// not general boolean logic (no OR, no parentheses).
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bytom/errors"
+)
+
+// Op is a condition's comparison operator.
+type Op string
+
+const (
+	OpEqual   Op = "="
+	OpLess    Op = "<"
+	OpGreater Op = ">"
+)
+
+// Condition is a single `key op value` clause. Value holds a string or an
+// int64 depending on whether the literal was quoted.
+type Condition struct {
+	Key   string
+	Op    Op
+	Value interface{}
+}
+
+// Query is an ordered list of Conditions, all of which must match (AND).
+type Query struct {
+	Conditions []Condition
+}
+
+// Parse parses a query string like `asset_id='USD' AND height>100`.
+func Parse(s string) (*Query, error) {
+	q := &Query{}
+	for _, clause := range strings.Split(s, " AND ") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		cond, err := parseCondition(clause)
+		if err != nil {
+			return nil, errors.WithDetailf(err, "parsing clause %q", clause)
+		}
+		q.Conditions = append(q.Conditions, cond)
+	}
+	if len(q.Conditions) == 0 {
+		return nil, errors.New("empty query")
+	}
+	return q, nil
+}
+
+func parseCondition(clause string) (Condition, error) {
+	idx, op := strings.IndexAny(clause, "=<>"), Op("")
+	if idx < 0 {
+		return Condition{}, errors.New("missing operator")
+	}
+	op = Op(clause[idx])
+
+	key := strings.TrimSpace(clause[:idx])
+	raw := strings.TrimSpace(clause[idx+1:])
+	if key == "" || raw == "" {
+		return Condition{}, errors.New("missing key or value")
+	}
+
+	if strings.HasPrefix(raw, "'") && strings.HasSuffix(raw, "'") && len(raw) >= 2 {
+		return Condition{Key: key, Op: op, Value: raw[1 : len(raw)-1]}, nil
+	}
+
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return Condition{}, errors.WithDetailf(err, "value %q is neither a quoted string nor an integer", raw)
+	}
+	return Condition{Key: key, Op: op, Value: n}, nil
+}
+
+// Match reports whether every condition in q is satisfied by fields,
+// a flat map of field name to either string or int64 value (the caller
+// merges tags and any synthetic fields like "height" into one map).
+func (q *Query) Match(fields map[string]interface{}) bool {
+	for _, cond := range q.Conditions {
+		v, ok := fields[cond.Key]
+		if !ok || !matchOne(cond, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchOne(cond Condition, v interface{}) bool {
+	switch want := cond.Value.(type) {
+	case string:
+		got, ok := v.(string)
+		return ok && cond.Op == OpEqual && got == want
+	case int64:
+		got, ok := toInt64(v)
+		if !ok {
+			return false
+		}
+		switch cond.Op {
+		case OpEqual:
+			return got == want
+		case OpLess:
+			return got < want
+		case OpGreater:
+			return got > want
+		}
+	}
+	return false
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case uint64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,45 @@
+package query
+
+import "testing"
+
+func TestParseAndMatch(t *testing.T) {
+	cases := []struct {
+		query   string
+		fields  map[string]interface{}
+		matches bool
+	}{
+		{`asset_id='USD'`, map[string]interface{}{"asset_id": "USD"}, true},
+		{`asset_id='USD'`, map[string]interface{}{"asset_id": "EUR"}, false},
+		{`height>100`, map[string]interface{}{"height": int64(101)}, true},
+		{`height>100`, map[string]interface{}{"height": int64(100)}, false},
+		{`height<100`, map[string]interface{}{"height": int64(99)}, true},
+		{
+			`asset_id='USD' AND height>100`,
+			map[string]interface{}{"asset_id": "USD", "height": int64(150)},
+			true,
+		},
+		{
+			`asset_id='USD' AND height>100`,
+			map[string]interface{}{"asset_id": "EUR", "height": int64(150)},
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		q, err := Parse(c.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.query, err)
+		}
+		if got := q.Match(c.fields); got != c.matches {
+			t.Errorf("Parse(%q).Match(%v) = %v, want %v", c.query, c.fields, got, c.matches)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{"", "asset_id", "asset_id=notquoted"} {
+		if _, err := Parse(q); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", q)
+		}
+	}
+}
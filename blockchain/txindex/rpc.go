@@ -0,0 +1,55 @@
+package txindex
+
+import (
+	"context"
+
+	"github.com/bytom/errors"
+	"github.com/bytom/protocol/bc"
+)
+
+// SearchResult is the JSON-RPC result shape for tx_search: the page of
+// matches plus the cursor to pass as "after" for the next page.
+type SearchResult struct {
+	Txs  []*TxResult `json:"txs"`
+	Next string      `json:"next"`
+}
+
+// Handler implements the "tx" and "tx_search" RPC methods on top of an
+// Indexer. node.Node registers Tx/Search with rpccore.Routes once the
+// indexer is built, so the two route names the request asks for have an
+// implementation behind them rather than just a stored Indexer reference.
+type Handler struct {
+	indexer Indexer
+}
+
+// NewHandler returns a Handler serving RPC requests out of indexer.
+func NewHandler(indexer Indexer) *Handler {
+	return &Handler{indexer: indexer}
+}
+
+// Tx serves the "tx" RPC route: a transaction by its hex-encoded hash.
+func (h *Handler) Tx(hashHex string) (*TxResult, error) {
+	var hash bc.Hash
+	if err := hash.UnmarshalText([]byte(hashHex)); err != nil {
+		return nil, errors.Wrap(err, "parsing tx hash")
+	}
+
+	result, err := h.indexer.Tx(context.Background(), hash)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errors.New("transaction not found")
+	}
+	return result, nil
+}
+
+// Search serves the "tx_search" RPC route: transactions matching a
+// predicate like `asset_id='X' AND height>100`, paged by limit/after.
+func (h *Handler) Search(query string, after string, limit int) (*SearchResult, error) {
+	txs, next, err := h.indexer.Search(context.Background(), query, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{Txs: txs, Next: next}, nil
+}
@@ -0,0 +1,49 @@
+// Package txindex indexes committed transactions so clients can look them
+// up by hash or search them by tag, honoring the INDEX_TRANSACTIONS env
+// var that node.NewNode already reads.
+package txindex
+
+import (
+	"context"
+
+	"github.com/bytom/protocol/bc"
+	"github.com/bytom/protocol/bc/legacy"
+)
+
+// TagKeys is the set of reference-data/action fields indexed alongside
+// every transaction, in addition to its hash. Removing a key stops that
+// field being tagged, so it can't be searched on; the transaction itself
+// is stored either way. Only these three fields are ever extracted, so a
+// key added here is never populated.
+var TagKeys = []string{"asset_id", "account_id", "reference_data"}
+
+// Tags is a flat set of indexed key/value pairs extracted from a
+// transaction for a given TagKeys entry.
+type Tags map[string]string
+
+// TxResult is what the indexer stores and returns for a single
+// transaction: the transaction itself, the height it landed in, and its
+// extracted tags.
+type TxResult struct {
+	Tx     *legacy.Tx `json:"transaction"`
+	Height uint64     `json:"block_height"`
+	Tags   Tags       `json:"tags"`
+}
+
+// Indexer is implemented by every indexing backend (null, kv, ...). It is
+// driven by block-committed events and answers by-hash and tag-query
+// lookups.
+type Indexer interface {
+	// IndexBlock indexes every transaction in b, tagging each with the
+	// fields named by TagKeys.
+	IndexBlock(ctx context.Context, b *legacy.Block) error
+
+	// Tx looks up a single transaction by hash. It returns nil, nil if
+	// the indexer has no record of it.
+	Tx(ctx context.Context, hash bc.Hash) (*TxResult, error)
+
+	// Search returns transactions matching query, a predicate like
+	// `asset_id='X' AND height>100`, most recent first, paged by
+	// limit/after.
+	Search(ctx context.Context, query string, after string, limit int) (results []*TxResult, next string, err error)
+}
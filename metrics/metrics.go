@@ -0,0 +1,158 @@
+// Package metrics provides the Prometheus instrumentation used across the
+// node: p2p, mempool, chain, RPC and wallet store counters/gauges/histograms.
+// Callers that don't want the overhead (tests, tools) can use NopMetrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// counterVec is the subset of *prometheus.CounterVec callers use. It lets
+// NopMetrics hand back a constant, never-registered Counter from
+// WithLabelValues instead of a real per-label vec, so disabled
+// instrumentation doesn't pay CounterVec's per-label map lookup on every
+// call site.
+type counterVec interface {
+	WithLabelValues(lvs ...string) prometheus.Counter
+}
+
+// histogramVec is the *prometheus.HistogramVec equivalent of counterVec.
+type histogramVec interface {
+	WithLabelValues(lvs ...string) prometheus.Observer
+}
+
+// Metrics bundles all of the collectors the node registers with a
+// prometheus.Registerer. A single instance is created in node.NewNode and
+// used by node.Node alone: it samples the gauges, times new-block events
+// and wraps its HTTP handlers. It isn't passed to the p2p switch, tx pool,
+// blockchain reactor or account/asset stores, so AccountStoreOps and
+// AssetStoreOps are never incremented.
+type Metrics struct {
+	PeerCount        prometheus.Gauge
+	MempoolSize      prometheus.Gauge
+	ChainHeight      prometheus.Gauge
+	BlockProcessTime prometheus.Histogram
+
+	RPCRequestCount   counterVec
+	RPCRequestLatency histogramVec
+
+	AccountStoreOps counterVec
+	AssetStoreOps   counterVec
+}
+
+// NewMetrics builds a Metrics bundle and registers all of its collectors
+// with reg under the "bytom" namespace.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	rpcRequestCount := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bytom",
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "Number of RPC requests received, by method.",
+	}, []string{"method"})
+	rpcRequestLatency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "bytom",
+		Subsystem: "rpc",
+		Name:      "request_seconds",
+		Help:      "RPC request latency, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	accountStoreOps := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bytom",
+		Subsystem: "account",
+		Name:      "store_ops_total",
+		Help:      "Account store operations, by op.",
+	}, []string{"op"})
+	assetStoreOps := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bytom",
+		Subsystem: "asset",
+		Name:      "store_ops_total",
+		Help:      "Asset store operations, by op.",
+	}, []string{"op"})
+
+	m := &Metrics{
+		PeerCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "bytom",
+			Subsystem: "p2p",
+			Name:      "peers",
+			Help:      "Number of connected peers.",
+		}),
+		MempoolSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "bytom",
+			Subsystem: "mempool",
+			Name:      "size",
+			Help:      "Number of transactions currently in the tx pool.",
+		}),
+		ChainHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "bytom",
+			Subsystem: "chain",
+			Name:      "height",
+			Help:      "Height of the best known block.",
+		}),
+		BlockProcessTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "bytom",
+			Subsystem: "chain",
+			Name:      "block_process_seconds",
+			Help:      "Time between consecutive new-block events; not the processing time of a single block.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		RPCRequestCount:   rpcRequestCount,
+		RPCRequestLatency: rpcRequestLatency,
+		AccountStoreOps:   accountStoreOps,
+		AssetStoreOps:     assetStoreOps,
+	}
+
+	reg.MustRegister(
+		m.PeerCount,
+		m.MempoolSize,
+		m.ChainHeight,
+		m.BlockProcessTime,
+		rpcRequestCount,
+		rpcRequestLatency,
+		accountStoreOps,
+		assetStoreOps,
+	)
+	return m
+}
+
+// nopCollector is a Counter and Observer that discards everything
+// written to it. A single instance backs every label combination
+// nopCounterVec/nopHistogramVec hand out, so NopMetrics never allocates
+// per-label state or does the map lookup WithLabelValues normally costs.
+type nopCollector struct {
+	prometheus.Counter
+}
+
+func newNopCollector() nopCollector {
+	return nopCollector{Counter: prometheus.NewCounter(prometheus.CounterOpts{Name: "nop"})}
+}
+
+func (nopCollector) Observe(float64) {}
+
+type nopCounterVec struct{ c prometheus.Counter }
+
+func (v nopCounterVec) WithLabelValues(lvs ...string) prometheus.Counter { return v.c }
+
+type nopHistogramVec struct{ o prometheus.Observer }
+
+func (v nopHistogramVec) WithLabelValues(lvs ...string) prometheus.Observer { return v.o }
+
+// NopMetrics returns a Metrics whose collectors are never registered with,
+// or even allocated per-label by, any registry: Gauges and the Histogram
+// are built once and left unregistered, and the four label-keyed
+// collectors share one discarding Counter/Observer rather than a real
+// CounterVec/HistogramVec. Use it for tests and nodes that run with
+// Instrumentation.Prometheus disabled, where the metrics call sites
+// should stay allocation-free.
+func NopMetrics() *Metrics {
+	discard := newNopCollector()
+	return &Metrics{
+		PeerCount:         prometheus.NewGauge(prometheus.GaugeOpts{Name: "nop_peer_count"}),
+		MempoolSize:       prometheus.NewGauge(prometheus.GaugeOpts{Name: "nop_mempool_size"}),
+		ChainHeight:       prometheus.NewGauge(prometheus.GaugeOpts{Name: "nop_chain_height"}),
+		BlockProcessTime:  prometheus.NewHistogram(prometheus.HistogramOpts{Name: "nop_block_process_seconds"}),
+		RPCRequestCount:   nopCounterVec{c: discard},
+		RPCRequestLatency: nopHistogramVec{o: discard},
+		AccountStoreOps:   nopCounterVec{c: discard},
+		AssetStoreOps:     nopCounterVec{c: discard},
+	}
+}
@@ -3,27 +3,32 @@ package node
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"net"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/bytom/beacon"
 	"github.com/bytom/blockchain/account"
 	"github.com/bytom/blockchain/asset"
-	"github.com/bytom/blockchain/pin"
-	"github.com/bytom/blockchain/pseudohsm"
 	"github.com/bytom/blockchain/txdb"
+	"github.com/bytom/blockchain/txindex"
 	"github.com/bytom/consensus"
 	"github.com/bytom/env"
 	"github.com/bytom/errors"
+	"github.com/bytom/metrics"
 	"github.com/bytom/net/http/reqid"
 	"github.com/bytom/protocol"
 	"github.com/bytom/protocol/bc/legacy"
 	"github.com/bytom/types"
 	"github.com/bytom/version"
 	"github.com/kr/secureheader"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tendermint/tmlibs/log"
 
 	bc "github.com/bytom/blockchain"
@@ -60,10 +65,39 @@ type Node struct {
 	evsw types.EventSwitch // pub/sub for services
 	//    blockStore       *bc.MemStore
 	blockStore   *txdb.Store
+	chain        *protocol.Chain
+	txPool       *protocol.TxPool
 	bcReactor    *bc.BlockchainReactor
 	accounts     *account.Manager
 	assets       *asset.Registry
-	rpcListeners []net.Listener // rpc servers
+	txIndexer    txindex.Indexer
+	beacon       beacon.RandomnessBeacon
+	rpcServers   []*http.Server // json-rpc/websocket servers, shut down gracefully
+	rpcListeners []net.Listener // grpc listener; grpccore isn't in this tree so there's no graceful Stop to call
+
+	metrics *metrics.Metrics
+
+	// pluggable services, registered via Register and built by startServices
+	lock         sync.Mutex
+	serviceFuncs []ServiceConstructor
+	services     []Service
+	serviceIndex map[reflect.Type]Service
+
+	// user-facing HTTP server, built by rpcInit; RegisterHandler attaches to it
+	mux           *http.ServeMux
+	coreHandler   *waitHandler
+	apiServer     *http.Server
+	profServer    *http.Server
+	metricsServer *http.Server
+
+	// rootCtx is canceled in OnStop, ahead of any graceful HTTP shutdown,
+	// so every long-running goroutine launched with it (the wallet's
+	// ProcessBlocks, the beacon's Run, the HSM health stream) gets a
+	// chance to unwind. The HTTP servers' Serve goroutines don't use it;
+	// OnStop ends those with server.Shutdown instead of a bare listener
+	// Close.
+	rootCtx    context.Context
+	cancelRoot context.CancelFunc
 }
 
 var (
@@ -116,15 +150,44 @@ func (wh *waitHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	wh.h.ServeHTTP(w, req)
 }
 
-func rpcInit(h *bc.BlockchainReactor, config *cfg.Config) {
-	// The waitHandler accepts incoming requests, but blocks until its underlying
-	// handler is set, when the second phase is complete.
-	var coreHandler waitHandler
-	coreHandler.wg.Add(1)
+// instrumentationInit builds the node's Metrics bundle and, if
+// config.Instrumentation.Prometheus is enabled, serves that bundle's own
+// registry via promhttp.HandlerFor at /metrics on
+// config.Instrumentation.PrometheusListenAddr. Callers always get a
+// usable *metrics.Metrics back, instrumented or not. The returned
+// *http.Server is nil unless instrumentation is enabled; OnStop shuts it
+// down gracefully rather than killing it outright.
+func instrumentationInit(config *cfg.Config, logger log.Logger) (*metrics.Metrics, *http.Server) {
+	if config.Instrumentation == nil || !config.Instrumentation.Prometheus {
+		return metrics.NopMetrics(), nil
+	}
+
+	reg := prometheus.NewRegistry()
+	m := metrics.NewMetrics(reg)
+
 	mux := http.NewServeMux()
-	mux.Handle("/", &coreHandler)
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: config.Instrumentation.PrometheusListenAddr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Prometheus server", "error", err)
+		}
+	}()
+
+	return m, server
+}
 
-	var handler http.Handler = mux
+// rpcInit builds the user-facing HTTP server and mux on n. The mux is
+// exposed through RegisterHandler so out-of-tree services can attach
+// endpoints to it; the "/" route itself waits (via n.coreHandler) until
+// the blockchain reactor service has been constructed.
+func (n *Node) rpcInit(config *cfg.Config) {
+	n.coreHandler = &waitHandler{}
+	n.coreHandler.wg.Add(1)
+	n.mux = http.NewServeMux()
+	n.mux.Handle("/", n.coreHandler)
+
+	var handler http.Handler = n.metricsHandler(n.mux)
 	//handler = core.AuthHandler(handler, raftDB, accessTokens, tlsConfig)
 	handler = RedirectHandler(handler)
 	handler = reqid.Handler(handler)
@@ -144,21 +207,26 @@ func rpcInit(h *bc.BlockchainReactor, config *cfg.Config) {
 		// https://github.com/golang/go/issues/17071
 		TLSNextProto: map[string]func(*http.Server, *tls.Conn, http.Handler){},
 	}
+	n.apiServer = server
 	listenAddr := env.String("LISTEN", config.ApiAddress)
 	listener, _ := net.Listen("tcp", *listenAddr)
 
 	// The `Serve` call has to happen in its own goroutine because
 	// it's blocking and we need to proceed to the rest of the core setup after
-	// we call it.
+	// we call it. OnStop calls server.Shutdown, which makes Serve return
+	// http.ErrServerClosed; that's an orderly exit, not a crash.
 	go func() {
-		err := server.Serve(listener)
-		bytomlog.Fatalkv(context.Background(), bytomlog.KeyError, errors.Wrap(err, "Serve"))
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			bytomlog.Fatalkv(context.Background(), bytomlog.KeyError, errors.Wrap(err, "Serve"))
+		}
 	}()
-	coreHandler.Set(h)
 }
 
 func NewNode(config *cfg.Config, logger log.Logger) *Node {
-	ctx := context.Background()
+	rootCtx, cancelRoot := context.WithCancel(context.Background())
+	ctx := rootCtx
+
+	nodeMetrics, metricsServer := instrumentationInit(config, logger)
 
 	// Get store
 	tx_db := dbm.NewDB("txdb", config.DBBackend, config.DBDir())
@@ -196,70 +264,6 @@ func NewNode(config *cfg.Config, logger log.Logger) *Node {
 		}
 	}
 
-	var accounts *account.Manager = nil
-	var assets *asset.Registry = nil
-	var pinStore *pin.Store = nil
-
-	if config.Wallet.Enable {
-		accounts_db := dbm.NewDB("account", config.DBBackend, config.DBDir())
-		acc_utxos_db := dbm.NewDB("accountutxos", config.DBBackend, config.DBDir())
-		pinStore = pin.NewStore(acc_utxos_db)
-		err = pinStore.LoadAll(ctx)
-		if err != nil {
-			bytomlog.Error(ctx, err)
-			return nil
-		}
-
-		pinHeight := store.Height()
-		if pinHeight > 0 {
-			pinHeight = pinHeight - 1
-		}
-
-		pins := []string{account.PinName, account.DeleteSpentsPinName}
-		for _, p := range pins {
-			err = pinStore.CreatePin(ctx, p, pinHeight)
-			if err != nil {
-				bytomlog.Fatalkv(ctx, bytomlog.KeyError, err)
-			}
-		}
-
-		accounts = account.NewManager(accounts_db, chain, pinStore)
-		go accounts.ProcessBlocks(ctx)
-
-		assets_db := dbm.NewDB("asset", config.DBBackend, config.DBDir())
-		assets = asset.NewRegistry(assets_db, chain)
-	}
-	//Todo HSM
-	/*
-		if config.HsmUrl != ""{
-			// todo remoteHSM
-			cmn.Exit(cmn.Fmt("not implement"))
-
-		} else {
-			hsm, err = pseudohsm.New(config.KeysDir())
-			if err != nil {
-				cmn.Exit(cmn.Fmt("initialize HSM failed: %v", err))
-			}
-		}*/
-
-	hsm, err := pseudohsm.New(config.KeysDir())
-	if err != nil {
-		cmn.Exit(cmn.Fmt("initialize HSM failed: %v", err))
-	}
-	bcReactor := bc.NewBlockchainReactor(
-		store,
-		chain,
-		txPool,
-		accounts,
-		assets,
-		hsm,
-		fastSync,
-		pinStore)
-
-	bcReactor.SetLogger(logger.With("module", "blockchain"))
-	sw.AddReactor("BLOCKCHAIN", bcReactor)
-
-	rpcInit(bcReactor, config)
 	// Optionally, start the pex reactor
 	var addrBook *p2p.AddrBook
 	if config.P2P.PexReactor {
@@ -270,16 +274,15 @@ func NewNode(config *cfg.Config, logger log.Logger) *Node {
 		sw.AddReactor("PEX", pexReactor)
 	}
 
-	// add the event switch to all services
-	// they should all satisfy events.Eventable
-	//SetEventSwitch(eventSwitch, bcReactor, mempoolReactor, consensusReactor)
-
 	// run the profile server
+	var profServer *http.Server
 	profileHost := config.ProfListenAddress
 	if profileHost != "" {
-
+		profServer = &http.Server{Addr: profileHost}
 		go func() {
-			logger.Error("Profile server", "error", http.ListenAndServe(profileHost, nil))
+			if err := profServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Profile server", "error", err)
+			}
 		}()
 	}
 
@@ -291,12 +294,94 @@ func NewNode(config *cfg.Config, logger log.Logger) *Node {
 		addrBook: addrBook,
 
 		evsw:       eventSwitch,
-		bcReactor:  bcReactor,
 		blockStore: store,
-		accounts:   accounts,
-		assets:     assets,
+		chain:      chain,
+		txPool:     txPool,
+
+		metrics:       nodeMetrics,
+		metricsServer: metricsServer,
+		profServer:    profServer,
+
+		rootCtx:    rootCtx,
+		cancelRoot: cancelRoot,
 	}
 	node.BaseService = *cmn.NewBaseService(logger, "Node", node)
+
+	// Components are registered as Services rather than hard-wired here, so
+	// out-of-tree callers can add their own via Register before the node
+	// starts. The account/asset/HSM/blockchain-reactor wiring that used to
+	// live inline in NewNode now lives in services_builtin.go.
+	if config.Wallet.Enable {
+		node.Register(walletServiceConstructor())
+	}
+	node.Register(hsmServiceConstructor())
+	node.Register(txIndexServiceConstructor(indexTxs))
+	node.Register(beaconServiceConstructor())
+
+	node.rpcInit(config)
+
+	if err := node.startServices(); err != nil {
+		cmn.Exit(cmn.Fmt("Failed to start services: %v", err))
+	}
+
+	// The blockchain reactor is owned solely by the p2p switch (AddReactor
+	// below), the same as the PEX reactor above: it's not registered
+	// through node.Register, because startServices (called just above)
+	// and Node.OnStop already start and stop every generic Service
+	// before sw.Start/Stop runs the same cycle for the switch's own
+	// reactors, and a reactor can't
+	// have two independent lifecycle owners. bcReactorServiceConstructor
+	// is still reused here for its wallet/hsm dependency wiring, just
+	// invoked directly instead of through Register/startServices.
+	bcService, err := bcReactorServiceConstructor(txPool, fastSync)(&ServiceContext{
+		config:   config,
+		evsw:     eventSwitch,
+		chain:    chain,
+		store:    store,
+		logger:   logger,
+		rootCtx:  rootCtx,
+		services: node.serviceIndex,
+	})
+	if err != nil {
+		cmn.Exit(cmn.Fmt("Failed to construct blockchain reactor: %v", err))
+	}
+	bcReactor, ok := bcService.(*bc.BlockchainReactor)
+	if !ok {
+		cmn.Exit(cmn.Fmt("blockchain reactor service has unexpected type: %T", bcService))
+	}
+	node.bcReactor = bcReactor
+	node.coreHandler.Set(bcReactor)
+	sw.AddReactor("BLOCKCHAIN", bcReactor)
+
+	if config.Wallet.Enable {
+		var wallet *walletService
+		if err := node.Service(&wallet); err != nil {
+			cmn.Exit(cmn.Fmt("wallet service not found: %v", err))
+		}
+		node.accounts = wallet.accounts
+		node.assets = wallet.assets
+		// AccountStoreOps/AssetStoreOps stay unincremented: instrumenting
+		// them means wrapping account.Manager's and asset.Registry's own
+		// store calls, and neither package is part of this tree.
+	}
+
+	var txIndexer txindex.Indexer
+	if err := node.Service(&txIndexer); err != nil {
+		cmn.Exit(cmn.Fmt("tx indexer service not found: %v", err))
+	}
+	node.txIndexer = txIndexer
+
+	var randomnessBeacon beacon.RandomnessBeacon
+	if err := node.Service(&randomnessBeacon); err != nil {
+		cmn.Exit(cmn.Fmt("beacon service not found: %v", err))
+	}
+	node.beacon = randomnessBeacon
+	chain.SetBeacon(randomnessBeacon)
+
+	// add the event switch to all services
+	// they should all satisfy events.Eventable
+	//SetEventSwitch(eventSwitch, bcReactor, mempoolReactor, consensusReactor)
+
 	return node
 }
 
@@ -324,23 +409,122 @@ func (n *Node) OnStart() error {
 	}
 	// Run the RPC server
 	if n.config.RPC.ListenAddress != "" {
-		listeners, err := n.startRPC()
-		if err != nil {
+		if err := n.startRPC(); err != nil {
 			return err
 		}
-		n.rpcListeners = listeners
 	}
 
+	go n.reportMetrics()
+	n.reportBlockLatency()
+	n.reportProposerSeed()
+
 	return nil
 }
 
+// reportMetrics periodically samples gauges that have no natural event to
+// hook into (peer count, mempool size, chain height).
+func (n *Node) reportMetrics() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		n.metrics.PeerCount.Set(float64(len(n.sw.Peers().List())))
+		n.metrics.ChainHeight.Set(float64(n.blockStore.Height()))
+		n.metrics.MempoolSize.Set(float64(len(n.txPool.GetTransactions())))
+	}
+}
+
+const blockLatencyListenerID = "metrics"
+
+// reportBlockLatency observes BlockProcessTime on every committed block.
+// It times the gap between consecutive EventStringNewBlock events rather
+// than true processing duration (start-of-processing to commit), since
+// that timestamp lives inside bc.BlockchainReactor, which isn't part of
+// this tree; the inter-block gap is the closest proxy observable from
+// node alone.
+func (n *Node) reportBlockLatency() {
+	last := time.Now()
+	types.AddListenerForEvent(n.evsw, blockLatencyListenerID, types.EventStringNewBlock(), func(ed types.EventData) {
+		now := time.Now()
+		n.metrics.BlockProcessTime.Observe(now.Sub(last).Seconds())
+		last = now
+	})
+}
+
+const proposerSeedListenerID = "beacon-proposer-seed"
+
+// reportProposerSeed feeds the beacon's randomness into proposer
+// selection and tx ordering on every committed block: it reads the
+// latest verified round, mixes it with that block's height via
+// beacon.MixSeed, and hands the result to chain.SetProposerSeed, the
+// seed protocol.Chain draws on for the next block. Mixing per block
+// rather than per round means a slow or stalled beacon leaves each new
+// block mixing in the last round it verified rather than blocking block
+// production; if no round can be read, the seed is left unchanged.
+func (n *Node) reportProposerSeed() {
+	types.AddListenerForEvent(n.evsw, proposerSeedListenerID, types.EventStringNewBlock(), func(ed types.EventData) {
+		block, ok := ed.(types.EventDataNewBlock)
+		if !ok {
+			return
+		}
+		round := n.beacon.LatestRound()
+		randomness, err := n.beacon.Entry(round)
+		if err != nil {
+			return
+		}
+
+		base := make([]byte, 8)
+		binary.BigEndian.PutUint64(base, block.Block.Height)
+		seed := beacon.MixSeed(beacon.Entry{Round: round, Randomness: randomness}, base)
+		n.chain.SetProposerSeed(seed)
+	})
+}
+
+// metricsHandler wraps next so every request that reaches it is counted
+// in RPCRequestCount and timed in RPCRequestLatency, labeled by the
+// request path: for the RPC mux built in startRPC that's "/"+funcName
+// (rpcserver.RegisterRPCFuncs' convention), and for the API mux built in
+// rpcInit it's whatever path the blockchain reactor routes on.
+func (n *Node) metricsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		n.metrics.RPCRequestCount.WithLabelValues(r.URL.Path).Inc()
+		n.metrics.RPCRequestLatency.WithLabelValues(r.URL.Path).Observe(time.Since(start).Seconds())
+	})
+}
+
 func (n *Node) OnStop() {
 	n.BaseService.OnStop()
 
 	n.Logger.Info("Stopping Node")
-	// TODO: gracefully disconnect from peers.
+	n.evsw.RemoveListener(blockLatencyListenerID)
+	n.evsw.RemoveListener(proposerSeedListenerID)
+
+	// Cancel the root context first so goroutines started with it
+	// (accounts.ProcessBlocks, ...) begin unwinding while we shut down
+	// the HTTP servers.
+	n.cancelRoot()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), n.config.GracefulShutdownTimeout)
+	defer cancel()
+	n.shutdownHTTPServer("api", n.apiServer, shutdownCtx)
+	n.shutdownHTTPServer("profile", n.profServer, shutdownCtx)
+	n.shutdownHTTPServer("metrics", n.metricsServer, shutdownCtx)
+	for i, server := range n.rpcServers {
+		n.shutdownHTTPServer(cmn.Fmt("rpc-%d", i), server, shutdownCtx)
+	}
+
+	n.stopServices()
+
+	// Gracefully disconnect from peers before tearing down the switch's
+	// own listeners, so in-flight peer messages have a chance to drain.
+	for _, peer := range n.sw.Peers().List() {
+		n.sw.StopPeerGracefully(peer)
+	}
 	n.sw.Stop()
 
+	// Only the grpc listener ends up here; the json-rpc/websocket
+	// listeners are owned by n.rpcServers and already drained above.
 	for _, l := range n.rpcListeners {
 		n.Logger.Info("Closing rpc listener", "listener", l)
 		if err := l.Close(); err != nil {
@@ -349,6 +533,17 @@ func (n *Node) OnStop() {
 	}
 }
 
+// shutdownHTTPServer gracefully shuts down server, bounded by ctx, if it
+// was ever started. name is only used for the log line.
+func (n *Node) shutdownHTTPServer(name string, server *http.Server, ctx context.Context) {
+	if server == nil {
+		return
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		n.Logger.Error("Error shutting down server", "server", name, "error", err)
+	}
+}
+
 func (n *Node) RunForever() {
 	// Sleep forever and then...
 	cmn.TrapSignal(func() {
@@ -377,10 +572,46 @@ func (n *Node) ConfigureRPC() {
 	rpccore.SetBlockStore(n.blockStore)
 	rpccore.SetSwitch(n.sw)
 	rpccore.SetAddrBook(n.addrBook)
+	rpccore.SetTxIndexer(n.txIndexer)
+	rpccore.SetBeacon(n.beacon)
 	rpccore.SetLogger(n.Logger.With("module", "rpc"))
+	n.registerIndexerRoutes()
+	n.registerBeaconRoutes()
+}
+
+// registerIndexerRoutes adds the "tx" and "tx_search" routes, served by
+// txindex.Handler, to rpccore.Routes, the same map startRPC hands to
+// rpcserver.RegisterRPCFuncs.
+func (n *Node) registerIndexerRoutes() {
+	h := txindex.NewHandler(n.txIndexer)
+	rpccore.Routes["tx"] = rpcserver.NewRPCFunc(h.Tx, "hash")
+	rpccore.Routes["tx_search"] = rpcserver.NewRPCFunc(h.Search, "query,after,limit")
+}
+
+// registerBeaconRoutes adds "beacon_latest" to rpccore.Routes so clients
+// can read the beacon's most recently verified round directly, instead
+// of trusting their own view of the world.
+func (n *Node) registerBeaconRoutes() {
+	rpccore.Routes["beacon_latest"] = rpcserver.NewRPCFunc(n.beaconLatest, "")
 }
 
-func (n *Node) startRPC() ([]net.Listener, error) {
+// beaconLatest serves the "beacon_latest" RPC route.
+func (n *Node) beaconLatest() (*beacon.Entry, error) {
+	round := n.beacon.LatestRound()
+	randomness, err := n.beacon.Entry(round)
+	if err != nil {
+		return nil, err
+	}
+	return &beacon.Entry{Round: round, Randomness: randomness}, nil
+}
+
+// startRPC builds the JSON-RPC/websocket mux for every configured
+// listen address and serves each on its own *http.Server (stored in
+// n.rpcServers) instead of handing the listener to
+// rpcserver.StartHTTPServer, which owns its http.Serve call and never
+// gives the caller anything to Shutdown gracefully. OnStop shuts these
+// down the same way it shuts down apiServer/profServer/metricsServer.
+func (n *Node) startRPC() error {
 	n.ConfigureRPC()
 	listenAddrs := strings.Split(n.config.RPC.ListenAddress, ",")
 
@@ -389,31 +620,42 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 	}
 
 	// we may expose the rpc over both a unix and tcp socket
-	listeners := make([]net.Listener, len(listenAddrs))
-	for i, listenAddr := range listenAddrs {
+	for _, listenAddr := range listenAddrs {
 		mux := http.NewServeMux()
 		wm := rpcserver.NewWebsocketManager(rpccore.Routes, n.evsw)
 		rpcLogger := n.Logger.With("module", "rpc-server")
 		wm.SetLogger(rpcLogger)
 		mux.HandleFunc("/websocket", wm.WebsocketHandler)
 		rpcserver.RegisterRPCFuncs(mux, rpccore.Routes, rpcLogger)
-		listener, err := rpcserver.StartHTTPServer(listenAddr, mux, rpcLogger)
+
+		proto, addr := ProtocolAndAddress(listenAddr)
+		listener, err := net.Listen(proto, addr)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		listeners[i] = listener
+
+		server := &http.Server{Handler: n.metricsHandler(mux)}
+		n.rpcServers = append(n.rpcServers, server)
+		go func() {
+			if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+				rpcLogger.Error("RPC server stopped", "error", err)
+			}
+		}()
 	}
 
-	// we expose a simplified api over grpc for convenience to app devs
+	// we expose a simplified api over grpc for convenience to app devs.
+	// grpccore isn't part of this tree, so there's no *grpc.Server to
+	// call GracefulStop on; the listener still gets closed in OnStop,
+	// same as before.
 	grpcListenAddr := n.config.RPC.GRPCListenAddress
 	if grpcListenAddr != "" {
 		listener, err := grpccore.StartGRPCServer(grpcListenAddr)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		listeners = append(listeners, listener)
+		n.rpcListeners = append(n.rpcListeners, listener)
 	}
-	return listeners, nil
+	return nil
 }
 
 func (n *Node) Switch() *p2p.Switch {
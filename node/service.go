@@ -0,0 +1,133 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/bytom/blockchain/txdb"
+	"github.com/bytom/errors"
+	"github.com/bytom/protocol"
+	"github.com/bytom/types"
+	"github.com/tendermint/tmlibs/log"
+
+	cfg "github.com/bytom/config"
+)
+
+var errUnknownService = errors.New("unknown service")
+
+// Service is implemented by any component that can be registered with a
+// Node via Register. The Start/Stop signatures mirror the cmn.BaseService
+// contract already used by the p2p switch and event switch in this
+// package, so most existing reactors satisfy Service without a wrapper.
+type Service interface {
+	Start() (bool, error)
+	Stop() bool
+}
+
+// ServiceConstructor builds a Service out of the node's shared state.
+// Constructors run in registration order, so a service that depends on
+// another (via ServiceContext.Service) must be registered after it.
+type ServiceConstructor func(ctx *ServiceContext) (Service, error)
+
+// ServiceContext carries the state a ServiceConstructor needs: the shared
+// event switch, chain, store, config and logger, plus a typed lookup for
+// services constructed earlier in the same Register order.
+type ServiceContext struct {
+	config *cfg.Config
+	evsw   types.EventSwitch
+	chain  *protocol.Chain
+	store  *txdb.Store
+	logger log.Logger
+
+	// rootCtx is canceled when the node stops; services that launch
+	// their own long-running goroutines (the wallet's ProcessBlocks
+	// loop, for instance) should derive their working context from it
+	// instead of context.Background().
+	rootCtx context.Context
+
+	services map[reflect.Type]Service
+}
+
+// Service populates target, a pointer to an interface or concrete service
+// type, with the already-constructed service assignable to that type.
+// It mirrors the dependency-lookup helper from the go-ethereum node
+// package, which this refactor is modelled on.
+func (ctx *ServiceContext) Service(target interface{}) error {
+	element := reflect.ValueOf(target).Elem()
+	for _, s := range ctx.services {
+		if reflect.TypeOf(s).AssignableTo(element.Type()) {
+			element.Set(reflect.ValueOf(s))
+			return nil
+		}
+	}
+	return errUnknownService
+}
+
+// Register schedules constructor to run when the node builds its
+// services. Out-of-tree callers use this instead of forking NewNode to
+// add a reactor.
+func (n *Node) Register(constructor ServiceConstructor) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.serviceFuncs = append(n.serviceFuncs, constructor)
+}
+
+// RegisterHandler attaches h at pattern on the user-facing HTTP server
+// created in rpcInit, so third parties (a GraphQL endpoint, a wallet UI)
+// can share the node's listener instead of standing up their own.
+func (n *Node) RegisterHandler(pattern string, h http.Handler) {
+	n.mux.Handle(pattern, h)
+}
+
+// startServices runs every registered ServiceConstructor in order and
+// starts the resulting services, recording them on the node so OnStop can
+// shut them down in the reverse of the order they were started and so later code in
+// NewNode can look one up via Service.
+func (n *Node) startServices() error {
+	ctx := &ServiceContext{
+		config:   n.config,
+		evsw:     n.evsw,
+		chain:    n.chain,
+		store:    n.blockStore,
+		logger:   n.Logger,
+		rootCtx:  n.rootCtx,
+		services: make(map[reflect.Type]Service),
+	}
+
+	for _, constructor := range n.serviceFuncs {
+		service, err := constructor(ctx)
+		if err != nil {
+			return errors.Wrap(err, "constructing service")
+		}
+		kind := reflect.TypeOf(service)
+		if _, dup := ctx.services[kind]; dup {
+			return fmt.Errorf("duplicate service: %v", kind)
+		}
+		ctx.services[kind] = service
+		n.services = append(n.services, service)
+	}
+	n.serviceIndex = ctx.services
+
+	for _, service := range n.services {
+		if _, err := service.Start(); err != nil {
+			return errors.Wrap(err, "starting service")
+		}
+	}
+	return nil
+}
+
+// Service populates target with the running service assignable to its
+// type, the same lookup ServiceContext.Service offers constructors, for
+// callers that need a service handle after startServices has returned.
+func (n *Node) Service(target interface{}) error {
+	return (&ServiceContext{services: n.serviceIndex}).Service(target)
+}
+
+// stopServices stops every started service in reverse start order.
+func (n *Node) stopServices() {
+	for i := len(n.services) - 1; i >= 0; i-- {
+		n.services[i].Stop()
+	}
+}
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"reflect"
+	"testing"
+)
+
+type stubService struct {
+	started bool
+	stopped bool
+}
+
+func (s *stubService) Start() (bool, error) { s.started = true; return true, nil }
+func (s *stubService) Stop() bool           { s.stopped = true; return true }
+
+func TestServiceContextServiceLookup(t *testing.T) {
+	svc := &stubService{}
+	ctx := &ServiceContext{services: map[reflect.Type]Service{reflect.TypeOf(svc): svc}}
+
+	var got *stubService
+	if err := ctx.Service(&got); err != nil {
+		t.Fatalf("Service: %v", err)
+	}
+	if got != svc {
+		t.Fatalf("Service returned %p, want %p", got, svc)
+	}
+}
+
+func TestServiceContextServiceNotFound(t *testing.T) {
+	ctx := &ServiceContext{services: map[reflect.Type]Service{}}
+	var got *stubService
+	if err := ctx.Service(&got); err != errUnknownService {
+		t.Fatalf("Service = %v, want errUnknownService", err)
+	}
+}
+
+func TestStartServicesStartsAndStopsRegisteredServices(t *testing.T) {
+	n := &Node{}
+	svc := &stubService{}
+	n.Register(func(ctx *ServiceContext) (Service, error) { return svc, nil })
+
+	if err := n.startServices(); err != nil {
+		t.Fatalf("startServices: %v", err)
+	}
+	if !svc.started {
+		t.Fatal("startServices did not start the registered service")
+	}
+
+	var got *stubService
+	if err := n.Service(&got); err != nil || got != svc {
+		t.Fatalf("Service() = %v, %v, want %p, nil", got, err, svc)
+	}
+
+	n.stopServices()
+	if !svc.stopped {
+		t.Fatal("stopServices did not stop the registered service")
+	}
+}
+
+func TestStartServicesRejectsDuplicateKind(t *testing.T) {
+	n := &Node{}
+	ctor := func(ctx *ServiceContext) (Service, error) { return &stubService{}, nil }
+	n.Register(ctor)
+	n.Register(ctor)
+
+	if err := n.startServices(); err == nil {
+		t.Fatal("startServices did not reject two services of the same concrete type")
+	}
+}
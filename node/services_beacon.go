@@ -0,0 +1,55 @@
+package node
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/bytom/beacon"
+	"github.com/bytom/errors"
+)
+
+// beaconService adapts a beacon.RandomnessBeacon, which exposes its
+// polling loop as Run(ctx) rather than Start/Stop, to the Service
+// interface, the same way walletService adapts accounts.ProcessBlocks.
+type beaconService struct {
+	beacon.RandomnessBeacon
+
+	rootCtx context.Context
+	cancel  context.CancelFunc
+}
+
+func (b *beaconService) Start() (bool, error) {
+	ctx, cancel := context.WithCancel(b.rootCtx)
+	b.cancel = cancel
+	go b.Run(ctx)
+	return true, nil
+}
+
+func (b *beaconService) Stop() bool {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	return true
+}
+
+// beaconServiceConstructor builds the node's RandomnessBeacon behind
+// config.Beacon: an HTTP drand-style client when a chain is configured,
+// otherwise a Local beacon, so downstream code can always depend on a
+// RandomnessBeacon being present.
+func beaconServiceConstructor() ServiceConstructor {
+	return func(ctx *ServiceContext) (Service, error) {
+		if ctx.config.Beacon == nil || len(ctx.config.Beacon.URLs) == 0 {
+			return &beaconService{RandomnessBeacon: beacon.NewLocal(), rootCtx: ctx.rootCtx}, nil
+		}
+
+		publicKey, err := hex.DecodeString(ctx.config.Beacon.PublicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding beacon public key")
+		}
+		client, err := beacon.NewHTTP(ctx.config.Beacon.URLs, ctx.config.Beacon.ChainHash, publicKey, ctx.config.Beacon.Period)
+		if err != nil {
+			return nil, err
+		}
+		return &beaconService{RandomnessBeacon: client, rootCtx: ctx.rootCtx}, nil
+	}
+}
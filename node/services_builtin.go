@@ -0,0 +1,154 @@
+package node
+
+import (
+	"context"
+
+	"github.com/bytom/blockchain/account"
+	"github.com/bytom/blockchain/asset"
+	"github.com/bytom/blockchain/pin"
+	"github.com/bytom/blockchain/pseudohsm"
+	"github.com/bytom/protocol"
+	"github.com/bytom/remotehsm"
+	dbm "github.com/tendermint/tmlibs/db"
+
+	bc "github.com/bytom/blockchain"
+)
+
+// walletService owns the account/asset managers and the pin store that
+// tracks how far the wallet has processed the chain. It's only
+// registered when config.Wallet.Enable is set.
+type walletService struct {
+	accounts *account.Manager
+	assets   *asset.Registry
+	pins     *pin.Store
+	rootCtx  context.Context
+	cancel   context.CancelFunc
+}
+
+func (w *walletService) Start() (bool, error) {
+	ctx, cancel := context.WithCancel(w.rootCtx)
+	w.cancel = cancel
+	go w.accounts.ProcessBlocks(ctx)
+	return true, nil
+}
+
+func (w *walletService) Stop() bool {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	return true
+}
+
+// walletServiceConstructor builds the walletService constructor used by
+// NewNode to migrate the account/asset wiring onto the Service framework.
+func walletServiceConstructor() ServiceConstructor {
+	return func(ctx *ServiceContext) (Service, error) {
+		accountsDB := dbm.NewDB("account", ctx.config.DBBackend, ctx.config.DBDir())
+		utxosDB := dbm.NewDB("accountutxos", ctx.config.DBBackend, ctx.config.DBDir())
+
+		pinStore := pin.NewStore(utxosDB)
+		bgCtx := context.Background()
+		if err := pinStore.LoadAll(bgCtx); err != nil {
+			return nil, err
+		}
+
+		pinHeight := ctx.store.Height()
+		if pinHeight > 0 {
+			pinHeight = pinHeight - 1
+		}
+		for _, p := range []string{account.PinName, account.DeleteSpentsPinName} {
+			if err := pinStore.CreatePin(bgCtx, p, pinHeight); err != nil {
+				return nil, err
+			}
+		}
+
+		accounts := account.NewManager(accountsDB, ctx.chain, pinStore)
+		assetsDB := dbm.NewDB("asset", ctx.config.DBBackend, ctx.config.DBDir())
+		assets := asset.NewRegistry(assetsDB, ctx.chain)
+
+		return &walletService{accounts: accounts, assets: assets, pins: pinStore, rootCtx: ctx.rootCtx}, nil
+	}
+}
+
+// hsmService wraps whichever bc.Signer the node is configured to use so
+// it can be looked up through ServiceContext by services that need one,
+// without those services needing to know whether it's local or remote.
+type hsmService struct {
+	hsm     bc.Signer
+	client  *remotehsm.Client
+	rootCtx context.Context
+}
+
+func (h *hsmService) Start() (bool, error) {
+	if h.client != nil {
+		go h.client.Healthy(h.rootCtx, func(ok bool) {})
+	}
+	return true, nil
+}
+
+func (h *hsmService) Stop() bool {
+	if h.client != nil {
+		h.client.Close()
+	}
+	return true
+}
+
+// hsmServiceConstructor picks between the local pseudo-HSM and a remote
+// one reached over gRPC based on config.HsmUrl, exactly as the TODO in
+// the original NewNode described.
+func hsmServiceConstructor() ServiceConstructor {
+	return func(ctx *ServiceContext) (Service, error) {
+		if ctx.config.HsmUrl == "" {
+			hsm, err := pseudohsm.New(ctx.config.KeysDir())
+			if err != nil {
+				return nil, err
+			}
+			return &hsmService{hsm: hsm, rootCtx: ctx.rootCtx}, nil
+		}
+
+		client, err := remotehsm.Dial(ctx.config.HsmUrl, *rootCAs)
+		if err != nil {
+			return nil, err
+		}
+		return &hsmService{hsm: client, client: client, rootCtx: ctx.rootCtx}, nil
+	}
+}
+
+// bcReactorServiceConstructor builds the blockchain reactor, pulling the
+// wallet and HSM services out of the context. It isn't registered
+// through Register: NewNode invokes it directly, after startServices has
+// constructed the wallet and HSM services it depends on.
+func bcReactorServiceConstructor(txPool *protocol.TxPool, fastSync bool) ServiceConstructor {
+	return func(ctx *ServiceContext) (Service, error) {
+		var wallet *walletService
+		var accounts *account.Manager
+		var assets *asset.Registry
+		var pinStore *pin.Store
+		if err := ctx.Service(&wallet); err == nil {
+			accounts = wallet.accounts
+			assets = wallet.assets
+			pinStore = wallet.pins
+		}
+
+		var hsm *hsmService
+		if err := ctx.Service(&hsm); err != nil {
+			return nil, err
+		}
+
+		// hsm.hsm is a bc.Signer (see blockchain/signer.go), backed by
+		// either *pseudohsm.HSM or *remotehsm.Client depending on
+		// config.HsmUrl; NewBlockchainReactor takes the interface
+		// directly so either one works here without a type assertion.
+		bcReactor := bc.NewBlockchainReactor(
+			ctx.store,
+			ctx.chain,
+			txPool,
+			accounts,
+			assets,
+			hsm.hsm,
+			fastSync,
+			pinStore)
+		bcReactor.SetLogger(ctx.logger.With("module", "blockchain"))
+		return bcReactor, nil
+	}
+}
@@ -0,0 +1,31 @@
+package node
+
+import (
+	"github.com/bytom/blockchain/txindex"
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+// nullTxIndexService adapts txindex.Null, which has no goroutines of its
+// own, to the Service interface so it can sit in the same registration
+// slot as the kv indexer.
+type nullTxIndexService struct {
+	txindex.Null
+}
+
+func (nullTxIndexService) Start() (bool, error) { return true, nil }
+func (nullTxIndexService) Stop() bool           { return true }
+
+// txIndexServiceConstructor builds the transaction indexer behind the
+// indexTxs flag: a real dbm.DB-backed indexer when enabled, otherwise a
+// no-op so RPC handlers can depend on an Indexer unconditionally.
+func txIndexServiceConstructor(indexTxs bool) ServiceConstructor {
+	return func(ctx *ServiceContext) (Service, error) {
+		if !indexTxs {
+			return nullTxIndexService{}, nil
+		}
+		txIndexDB := dbm.NewDB("txindex", ctx.config.DBBackend, ctx.config.DBDir())
+		kv := txindex.NewKV(txIndexDB, ctx.evsw)
+		kv.SetLogger(ctx.logger.With("module", "txindex"))
+		return kv, nil
+	}
+}
@@ -0,0 +1,174 @@
+// Package remotehsm lets the node delegate signing to a remote HSM over
+// gRPC instead of holding keys in-process via pseudohsm. Client
+// implements blockchain.Signer; Server wraps a pseudohsm.HSM to serve the
+// other side of the same protocol.
+package remotehsm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"time"
+
+	bc "github.com/bytom/blockchain"
+	"github.com/bytom/env"
+	"github.com/bytom/errors"
+	"github.com/bytom/remotehsm/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	clientCertFile = env.String("HSM_CLIENT_CERT", "") // file path
+	clientKeyFile  = env.String("HSM_CLIENT_KEY", "")  // file path
+)
+
+const (
+	dialTimeout    = 10 * time.Second
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+// Client is a blockchain.Signer backed by a remote signer reached over
+// mutually-authenticated gRPC.
+type Client struct {
+	conn *grpc.ClientConn
+	rpc  pb.RemoteHSMClient
+}
+
+// Dial connects to a remote HSM at addr. It uses the existing
+// ROOT_CA_CERTS env var for the server's CA and the new
+// HSM_CLIENT_CERT/HSM_CLIENT_KEY for the client's own mTLS identity, and
+// blocks for up to dialTimeout in total while gRPC retries the initial
+// connection with exponential backoff capped at maxBackoff.
+func Dial(addr, rootCAFile string) (*Client, error) {
+	creds, err := clientTLS(rootCAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "building HSM client TLS config")
+	}
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout),
+		grpc.WithBackoffMaxDelay(maxBackoff),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "dialing remote HSM")
+	}
+
+	return &Client{conn: conn, rpc: pb.NewRemoteHSMClient(conn)}, nil
+}
+
+func clientTLS(rootCAFile string) (credentials.TransportCredentials, error) {
+	rootCA, err := ioutil.ReadFile(rootCAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading ROOT_CA_CERTS")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(rootCA) {
+		return nil, errors.New("no certificates found in ROOT_CA_CERTS")
+	}
+
+	cert, err := tls.LoadX509KeyPair(*clientCertFile, *clientKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading HSM_CLIENT_CERT/HSM_CLIENT_KEY")
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+	}), nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Healthy streams liveness updates from the remote signer until ctx is
+// canceled, invoking onChange with the status carried by every response
+// the signer sends. A failed attempt to open the stream is retried with
+// exponential backoff; a stream that drops after opening is reopened
+// straight away.
+func (c *Client) Healthy(ctx context.Context, onChange func(ok bool)) {
+	backoff := initialBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		stream, err := c.rpc.Health(ctx, &pb.HealthRequest{})
+		if err != nil {
+			time.Sleep(backoff)
+			backoff = nextBackoff(backoff)
+			continue
+		}
+		backoff = initialBackoff
+
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				break
+			}
+			onChange(resp.Ok)
+		}
+	}
+}
+
+func nextBackoff(cur time.Duration) time.Duration {
+	next := cur * 2
+	if next > maxBackoff {
+		return maxBackoff
+	}
+	return next
+}
+
+func (c *Client) ListKeys(ctx context.Context) ([]bc.XPub, error) {
+	resp, err := c.rpc.ListKeys(ctx, &pb.ListKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]bc.XPub, len(resp.Keys))
+	for i, k := range resp.Keys {
+		keys[i] = fromPB(k)
+	}
+	return keys, nil
+}
+
+func (c *Client) XPub(ctx context.Context, keyID string) (*bc.XPub, error) {
+	resp, err := c.rpc.XPub(ctx, &pb.XPubRequest{KeyId: keyID})
+	if err != nil {
+		return nil, err
+	}
+	xpub := fromPB(resp.Xpub)
+	return &xpub, nil
+}
+
+func (c *Client) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	resp, err := c.rpc.Sign(ctx, &pb.SignRequest{KeyId: keyID, Digest: digest})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+func (c *Client) CreateKey(ctx context.Context, alias, password string) (*bc.XPub, error) {
+	resp, err := c.rpc.CreateKey(ctx, &pb.CreateKeyRequest{Alias: alias, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	xpub := fromPB(resp.Xpub)
+	return &xpub, nil
+}
+
+var _ bc.Signer = (*Client)(nil)
+
+func fromPB(k *pb.XPub) bc.XPub {
+	if k == nil {
+		return bc.XPub{}
+	}
+	return bc.XPub{KeyID: k.KeyId, Alias: k.Alias, XPub: k.Xpub}
+}
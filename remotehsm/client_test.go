@@ -0,0 +1,23 @@
+package remotehsm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBackoffDoublesAndCaps(t *testing.T) {
+	cases := []struct {
+		cur  time.Duration
+		want time.Duration
+	}{
+		{initialBackoff, 2 * initialBackoff},
+		{maxBackoff / 2, maxBackoff},
+		{maxBackoff, maxBackoff},
+		{maxBackoff * 2, maxBackoff},
+	}
+	for _, c := range cases {
+		if got := nextBackoff(c.cur); got != c.want {
+			t.Errorf("nextBackoff(%v) = %v, want %v", c.cur, got, c.want)
+		}
+	}
+}
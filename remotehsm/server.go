@@ -0,0 +1,77 @@
+package remotehsm
+
+import (
+	"context"
+
+	bc "github.com/bytom/blockchain"
+	"github.com/bytom/blockchain/pseudohsm"
+	"github.com/bytom/remotehsm/pb"
+)
+
+// Server implements pb.RemoteHSMServer by delegating to a local
+// pseudohsm.HSM, so an operator can run the same signer a node would use
+// in-process behind this package's gRPC protocol instead.
+type Server struct {
+	hsm *pseudohsm.HSM
+}
+
+// NewServer wraps hsm to serve it over the RemoteHSM protocol.
+func NewServer(hsm *pseudohsm.HSM) *Server {
+	return &Server{hsm: hsm}
+}
+
+func (s *Server) ListKeys(ctx context.Context, _ *pb.ListKeysRequest) (*pb.ListKeysResponse, error) {
+	keys, err := s.hsm.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListKeysResponse{Keys: make([]*pb.XPub, len(keys))}
+	for i, k := range keys {
+		resp.Keys[i] = toPB(k)
+	}
+	return resp, nil
+}
+
+func (s *Server) XPub(ctx context.Context, req *pb.XPubRequest) (*pb.XPubResponse, error) {
+	xpub, err := s.hsm.XPub(ctx, req.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.XPubResponse{Xpub: toPB(*xpub)}, nil
+}
+
+func (s *Server) Sign(ctx context.Context, req *pb.SignRequest) (*pb.SignResponse, error) {
+	sig, err := s.hsm.Sign(ctx, req.KeyId, req.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignResponse{Signature: sig}, nil
+}
+
+func (s *Server) CreateKey(ctx context.Context, req *pb.CreateKeyRequest) (*pb.CreateKeyResponse, error) {
+	xpub, err := s.hsm.CreateKey(ctx, req.Alias, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateKeyResponse{Xpub: toPB(*xpub)}, nil
+}
+
+// Health reports the signer as healthy for as long as the stream is open:
+// it sends one response and then blocks until the client disconnects or
+// cancels, rather than returning right away, so Client.Healthy sees a
+// long-lived stream instead of redialing in a tight loop. A real
+// deployment would also push a new HealthResponse here whenever the
+// underlying keystore's liveness actually changes; this signer has
+// nothing but "the process is up" to report, so one response for the
+// life of the stream is all there is to send.
+func (s *Server) Health(_ *pb.HealthRequest, stream pb.RemoteHSM_HealthServer) error {
+	if err := stream.Send(&pb.HealthResponse{Ok: true}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func toPB(x bc.XPub) *pb.XPub {
+	return &pb.XPub{KeyId: x.KeyID, Alias: x.Alias, Xpub: x.XPub}
+}